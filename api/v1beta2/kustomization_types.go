@@ -36,6 +36,25 @@ const (
 	DisabledValue             = "disabled"
 )
 
+// BuildMetadata is a kustomize build option that annotates or labels the
+// resources generated by the build with provenance metadata.
+// +kubebuilder:validation:Enum=originAnnotations;transformerAnnotations;managedByLabel
+type BuildMetadata string
+
+const (
+	// OriginAnnotations adds the `config.kubernetes.io/origin` annotation
+	// to the build output.
+	OriginAnnotations BuildMetadata = "originAnnotations"
+
+	// TransformerAnnotations adds the `config.kubernetes.io/transformations`
+	// annotation to the build output.
+	TransformerAnnotations BuildMetadata = "transformerAnnotations"
+
+	// ManagedByLabel adds the `app.kubernetes.io/managed-by` label to the
+	// build output.
+	ManagedByLabel BuildMetadata = "managedByLabel"
+)
+
 // KustomizationSpec defines the configuration to calculate the desired state from a Source using Kustomize.
 type KustomizationSpec struct {
 	// DependsOn may contain a dependency.CrossNamespaceDependencyReference slice
@@ -44,6 +63,12 @@ type KustomizationSpec struct {
 	// +optional
 	DependsOn []dependency.CrossNamespaceDependencyReference `json:"dependsOn,omitempty"`
 
+	// CommonMetadata specifies the common labels and annotations that are
+	// applied to all resources. Any existing label or annotation will be
+	// overridden if its key matches a common one.
+	// +optional
+	CommonMetadata *CommonMetadata `json:"commonMetadata,omitempty"`
+
 	// Decrypt Kubernetes secrets before applying them on the cluster.
 	// +optional
 	Decryption *Decryption `json:"decryption,omitempty"`
@@ -59,7 +84,10 @@ type KustomizationSpec struct {
 	RetryInterval *metav1.Duration `json:"retryInterval,omitempty"`
 
 	// The KubeConfig for reconciling the Kustomization on a remote cluster.
-	// When specified, KubeConfig takes precedence over ServiceAccountName.
+	// When specified, the KubeConfig secret is used to reach the remote
+	// cluster, and ServiceAccountName, if also set, is impersonated for
+	// the apply, prune and health-check calls performed against it, unless
+	// KubeConfig.Impersonate is set, in which case that is used instead.
 	// +optional
 	KubeConfig *KubeConfig `json:"kubeConfig,omitempty"`
 
@@ -82,6 +110,12 @@ type KustomizationSpec struct {
 	// +optional
 	HealthChecks []meta.NamespacedObjectKindReference `json:"healthChecks,omitempty"`
 
+	// HealthCheckExprs is a list of CEL expressions used to assess the
+	// health of resources that kstatus cannot assess natively, for
+	// example CRDs not following the upstream status conventions.
+	// +optional
+	HealthCheckExprs []HealthCheckExpr `json:"healthCheckExprs,omitempty"`
+
 	// Strategic merge and JSON patches, defined as inline YAML objects,
 	// capable of targeting objects based on kind, label and annotation selectors.
 	// +optional
@@ -103,6 +137,37 @@ type KustomizationSpec struct {
 	// +optional
 	Images []kustomize.Image `json:"images,omitempty"`
 
+	// Components specifies relative paths to specifications of other Components
+	// that this kustomization wants to use. This is the CLI equivalent of using
+	// the `components` field in `kustomization.yaml`. Paths must be relative to
+	// the source root and must not escape it with a `..` path segment; the
+	// controller rejects the Kustomization otherwise.
+	// +optional
+	Components []string `json:"components,omitempty"`
+
+	// NamePrefix will prefix the names of all resources. It is concatenated
+	// with the resource's own name by kustomize, so unlike TargetNamespace it
+	// is not required to be a valid name on its own and may start or end
+	// with a `-`, e.g. "prod-".
+	// +kubebuilder:validation:Pattern="^[a-z0-9-]*$"
+	// +optional
+	NamePrefix string `json:"namePrefix,omitempty"`
+
+	// NameSuffix will suffix the names of all resources. It is concatenated
+	// with the resource's own name by kustomize, so unlike TargetNamespace it
+	// is not required to be a valid name on its own and may start or end
+	// with a `-`, e.g. "-v1".
+	// +kubebuilder:validation:Pattern="^[a-z0-9-]*$"
+	// +optional
+	NameSuffix string `json:"nameSuffix,omitempty"`
+
+	// BuildMetadata is a list of metadata types to annotate and label
+	// resources with on kustomize build. The controller merges the values
+	// into the `buildMetadata` field of the generated kustomization.yaml,
+	// the native kustomize mechanism for enabling these build options.
+	// +optional
+	BuildMetadata []BuildMetadata `json:"buildMetadata,omitempty"`
+
 	// The name of the Kubernetes service account to impersonate
 	// when reconciling this Kustomization.
 	// +optional
@@ -147,6 +212,38 @@ type KustomizationSpec struct {
 	Validation string `json:"validation,omitempty"`
 }
 
+// CommonMetadata defines the common labels and annotations that are
+// applied to all resources applied by the Kustomization.
+type CommonMetadata struct {
+	// Labels to be added to the object's metadata.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations to be added to the object's metadata.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// HealthCheckExpr defines a CEL expression used to assess the health of
+// resources matching a given apiVersion and kind, for resources kstatus
+// cannot assess natively.
+type HealthCheckExpr struct {
+	// APIVersion of the resources to run the expression against.
+	// +required
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the resources to run the expression against.
+	// +required
+	Kind string `json:"kind"`
+
+	// Expr is a CEL expression evaluated against the live object, with
+	// `status`, `metadata` and `spec` bound to the corresponding fields of
+	// the object. The resource is considered healthy once Expr evaluates
+	// to true, within Timeout.
+	// +required
+	Expr string `json:"expr"`
+}
+
 // Decryption defines how decryption is handled for Kubernetes manifests.
 type Decryption struct {
 	// Provider is the name of the decryption engine.
@@ -157,6 +254,32 @@ type Decryption struct {
 	// The secret name containing the private OpenPGP keys used for decryption.
 	// +optional
 	SecretRef *meta.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// AgeSecretRef references a secret containing the age private key(s)
+	// (an `age.agekey` file) used for decryption.
+	// +optional
+	AgeSecretRef *meta.LocalObjectReference `json:"ageSecretRef,omitempty"`
+
+	// VaultTokenSecretRef references a secret containing the `VAULT_TOKEN`
+	// and `VAULT_ADDR` keys used to authenticate with a HashiCorp Vault
+	// transit engine.
+	// +optional
+	VaultTokenSecretRef *meta.LocalObjectReference `json:"vaultTokenSecretRef,omitempty"`
+
+	// AWSSecretRef references a secret containing the AWS credentials
+	// used to decrypt with an AWS KMS master key.
+	// +optional
+	AWSSecretRef *meta.LocalObjectReference `json:"awsSecretRef,omitempty"`
+
+	// AzureKeyVaultSecretRef references a secret containing the Azure
+	// credentials used to decrypt with an Azure Key Vault master key.
+	// +optional
+	AzureKeyVaultSecretRef *meta.LocalObjectReference `json:"azureKeyVaultSecretRef,omitempty"`
+
+	// GCPServiceAccountSecretRef references a secret containing the GCP
+	// service account JSON key used to decrypt with a GCP KMS master key.
+	// +optional
+	GCPServiceAccountSecretRef *meta.LocalObjectReference `json:"gcpServiceAccountSecretRef,omitempty"`
 }
 
 // KubeConfig references a Kubernetes secret that contains a kubeconfig file.
@@ -171,6 +294,29 @@ type KubeConfig struct {
 	// the Kustomization.
 	// +required
 	SecretRef meta.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// Impersonate contains the impersonation config to be used for the
+	// apply, prune and health-check calls against the remote cluster
+	// reached through SecretRef. When not set, the controller falls back
+	// to impersonating KustomizationSpec.ServiceAccountName, if any.
+	// +optional
+	Impersonate *ImpersonationConfig `json:"impersonate,omitempty"`
+}
+
+// ImpersonationConfig defines the user, groups and UID to impersonate
+// for requests made against a remote cluster.
+type ImpersonationConfig struct {
+	// UserName is the username to impersonate.
+	// +optional
+	UserName string `json:"userName,omitempty"`
+
+	// Groups are the groups to impersonate.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+
+	// UID is the uid to impersonate.
+	// +optional
+	UID string `json:"uid,omitempty"`
 }
 
 // PostBuild describes which actions to perform on the YAML manifest
@@ -243,7 +389,7 @@ func KustomizationProgressing(k Kustomization, message string) Kustomization {
 
 // SetKustomizationHealthiness sets the HealthyCondition status for a Kustomization.
 func SetKustomizationHealthiness(k *Kustomization, status metav1.ConditionStatus, reason, message string) {
-	if !k.Spec.Wait && len(k.Spec.HealthChecks) == 0 {
+	if !k.Spec.Wait && len(k.Spec.HealthChecks) == 0 && len(k.Spec.HealthCheckExprs) == 0 {
 		apimeta.RemoveStatusCondition(k.GetStatusConditions(), HealthyCondition)
 	} else {
 		meta.SetResourceCondition(k, HealthyCondition, status, reason, trimString(message, MaxConditionMessageLength))
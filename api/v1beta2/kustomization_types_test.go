@@ -0,0 +1,44 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"regexp"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// namePrefixSuffixPattern mirrors the +kubebuilder:validation:Pattern marker
+// on NamePrefix/NameSuffix. There's no envtest apiserver in this package to
+// validate against, so this test guards the pattern string itself against
+// regressing to something that rejects the leading/trailing hyphens
+// kustomize's own namePrefix/nameSuffix concatenation relies on.
+const namePrefixSuffixPattern = `^[a-z0-9-]*$`
+
+func TestNamePrefixSuffixPattern(t *testing.T) {
+	g := NewWithT(t)
+	re := regexp.MustCompile(namePrefixSuffixPattern)
+
+	for _, valid := range []string{"", "prod-", "-v1", "tenant-a-", "a", "123"} {
+		g.Expect(re.MatchString(valid)).To(BeTrue(), "expected %q to match", valid)
+	}
+
+	for _, invalid := range []string{"Prod-", "prod_", "prod.", "prod "} {
+		g.Expect(re.MatchString(invalid)).To(BeFalse(), "expected %q not to match", invalid)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package build runs the kustomize build for a Kustomization's overlay and
+// post-processes the resulting objects according to the Kustomization spec,
+// before they are handed off to the applier.
+package build
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+)
+
+// Build generates the kustomization.yaml overlay at path within fs from the
+// spec's Components, NamePrefix, NameSuffix and BuildMetadata, runs the
+// kustomize build for it, and returns the resulting objects with the spec's
+// CommonMetadata merged in.
+func Build(spec kustomizev1.KustomizationSpec, fs filesys.FileSystem, path string) ([]*unstructured.Unstructured, error) {
+	if err := GenerateOverlay(fs, path, spec); err != nil {
+		return nil, fmt.Errorf("failed to generate kustomization overlay: %w", err)
+	}
+
+	k := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+
+	resMap, err := k.Run(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("kustomize build failed: %w", err)
+	}
+
+	objects := make([]*unstructured.Unstructured, 0, resMap.Size())
+	for _, res := range resMap.Resources() {
+		data, err := res.Map()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert built resource %s to an object: %w", res.CurId(), err)
+		}
+		objects = append(objects, &unstructured.Unstructured{Object: data})
+	}
+
+	ApplyCommonMetadata(objects, spec.CommonMetadata)
+
+	return objects, nil
+}
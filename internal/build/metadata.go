@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+)
+
+// ApplyCommonMetadata merges the labels and annotations of metadata onto
+// every object in objects, overriding any existing key that collides with
+// one defined in metadata. It is a no-op when metadata is nil.
+func ApplyCommonMetadata(objects []*unstructured.Unstructured, metadata *kustomizev1.CommonMetadata) {
+	if metadata == nil {
+		return
+	}
+
+	for _, obj := range objects {
+		if len(metadata.Labels) > 0 {
+			labels := obj.GetLabels()
+			if labels == nil {
+				labels = make(map[string]string, len(metadata.Labels))
+			}
+			for k, v := range metadata.Labels {
+				labels[k] = v
+			}
+			obj.SetLabels(labels)
+		}
+
+		if len(metadata.Annotations) > 0 {
+			annotations := obj.GetAnnotations()
+			if annotations == nil {
+				annotations = make(map[string]string, len(metadata.Annotations))
+			}
+			for k, v := range metadata.Annotations {
+				annotations[k] = v
+			}
+			obj.SetAnnotations(annotations)
+		}
+	}
+}
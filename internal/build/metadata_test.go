@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+)
+
+func TestApplyCommonMetadata(t *testing.T) {
+	t.Run("nil metadata is a no-op", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		ApplyCommonMetadata([]*unstructured.Unstructured{obj}, nil)
+
+		g.Expect(obj.GetLabels()).To(BeEmpty())
+	})
+
+	t.Run("merges and overrides colliding keys", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		obj.SetLabels(map[string]string{"app.kubernetes.io/name": "keep-me", "env": "overridden"})
+		obj.SetAnnotations(map[string]string{"keep": "me"})
+
+		ApplyCommonMetadata([]*unstructured.Unstructured{obj}, &kustomizev1.CommonMetadata{
+			Labels: map[string]string{
+				"env": "prod",
+			},
+			Annotations: map[string]string{
+				"cost-center": "123",
+			},
+		})
+
+		g.Expect(obj.GetLabels()).To(Equal(map[string]string{
+			"app.kubernetes.io/name": "keep-me",
+			"env":                    "prod",
+		}))
+		g.Expect(obj.GetAnnotations()).To(Equal(map[string]string{
+			"keep":        "me",
+			"cost-center": "123",
+		}))
+	})
+}
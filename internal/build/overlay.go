@@ -0,0 +1,124 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/konfig"
+	kusttypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+)
+
+// ValidateComponentPaths ensures that every component path is relative to
+// the source root and does not escape it through a ".." path segment.
+func ValidateComponentPaths(components []string) error {
+	for _, c := range components {
+		if filepath.IsAbs(c) {
+			return fmt.Errorf("component path %q must be relative to the source root", c)
+		}
+		for _, segment := range strings.Split(filepath.ToSlash(filepath.Clean(c)), "/") {
+			if segment == ".." {
+				return fmt.Errorf("component path %q must not escape the source root", c)
+			}
+		}
+	}
+	return nil
+}
+
+// GenerateOverlay ensures that the kustomization.yaml at path within fs
+// carries the Components, NamePrefix, NameSuffix and BuildMetadata from
+// spec, merging them into the file if one already exists there, or
+// generating one otherwise. It is a no-op when none of these fields are set.
+func GenerateOverlay(fs filesys.FileSystem, path string, spec kustomizev1.KustomizationSpec) error {
+	if err := ValidateComponentPaths(spec.Components); err != nil {
+		return err
+	}
+
+	if len(spec.Components) == 0 && spec.NamePrefix == "" && spec.NameSuffix == "" && len(spec.BuildMetadata) == 0 {
+		return nil
+	}
+
+	kfile := kustomizationFilePath(fs, path)
+
+	kus := &kusttypes.Kustomization{}
+	if fs.Exists(kfile) {
+		data, err := fs.ReadFile(kfile)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", kfile, err)
+		}
+		if err := yaml.Unmarshal(data, kus); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", kfile, err)
+		}
+	} else {
+		kus.TypeMeta = kusttypes.TypeMeta{
+			APIVersion: kusttypes.KustomizationVersion,
+			Kind:       kusttypes.KustomizationKind,
+		}
+	}
+
+	kus.Components = mergeUniqueStrings(kus.Components, spec.Components)
+
+	if spec.NamePrefix != "" {
+		kus.NamePrefix = spec.NamePrefix
+	}
+	if spec.NameSuffix != "" {
+		kus.NameSuffix = spec.NameSuffix
+	}
+
+	for _, bm := range spec.BuildMetadata {
+		kus.BuildMetadata = mergeUniqueStrings(kus.BuildMetadata, []string{string(bm)})
+	}
+
+	data, err := yaml.Marshal(kus)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", kfile, err)
+	}
+
+	return fs.WriteFile(kfile, data)
+}
+
+// kustomizationFilePath returns the path to the kustomization file at path,
+// preferring one that already exists, and falling back to the default
+// kustomization.yaml name otherwise.
+func kustomizationFilePath(fs filesys.FileSystem, path string) string {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		candidate := filepath.Join(path, name)
+		if fs.Exists(candidate) {
+			return candidate
+		}
+	}
+	return filepath.Join(path, konfig.DefaultKustomizationFileName())
+}
+
+func mergeUniqueStrings(existing, additional []string) []string {
+	seen := make(map[string]struct{}, len(existing)+len(additional))
+	merged := make([]string, 0, len(existing)+len(additional))
+	for _, v := range append(existing, additional...) {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		merged = append(merged, v)
+	}
+	return merged
+}
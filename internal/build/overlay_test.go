@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/api/filesys"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+)
+
+func TestValidateComponentPaths(t *testing.T) {
+	tests := []struct {
+		name       string
+		components []string
+		wantErr    bool
+	}{
+		{name: "relative paths are fine", components: []string{"components/a", "./components/b"}, wantErr: false},
+		{name: "absolute path is rejected", components: []string{"/etc/passwd"}, wantErr: true},
+		{name: "path escaping the root is rejected", components: []string{"../../etc/passwd"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := ValidateComponentPaths(tt.components)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestGenerateOverlay(t *testing.T) {
+	g := NewWithT(t)
+
+	fs := filesys.MakeFsInMemory()
+	g.Expect(fs.Mkdir("/overlay")).To(Succeed())
+	g.Expect(fs.WriteFile("/overlay/kustomization.yaml", []byte(`
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+  - deployment.yaml
+`))).To(Succeed())
+
+	spec := kustomizev1.KustomizationSpec{
+		Components:    []string{"components/a"},
+		NamePrefix:    "prod-",
+		NameSuffix:    "-v1",
+		BuildMetadata: []kustomizev1.BuildMetadata{kustomizev1.ManagedByLabel},
+	}
+
+	g.Expect(GenerateOverlay(fs, "/overlay", spec)).To(Succeed())
+
+	data, err := fs.ReadFile("/overlay/kustomization.yaml")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(data)).To(ContainSubstring("prod-"))
+	g.Expect(string(data)).To(ContainSubstring("-v1"))
+	g.Expect(string(data)).To(ContainSubstring("managedByLabel"))
+}
+
+func TestGenerateOverlayRejectsEscapingComponent(t *testing.T) {
+	g := NewWithT(t)
+
+	fs := filesys.MakeFsInMemory()
+	g.Expect(fs.Mkdir("/overlay")).To(Succeed())
+
+	spec := kustomizev1.KustomizationSpec{
+		Components: []string{"../../outside"},
+	}
+
+	err := GenerateOverlay(fs, "/overlay", spec)
+	g.Expect(err).To(HaveOccurred())
+}
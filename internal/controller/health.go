@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+)
+
+// celEnv is the CEL environment used to compile every HealthCheckExpr. It
+// exposes status, metadata and spec, bound to the corresponding top-level
+// fields of the live object being assessed.
+var celEnv, celEnvErr = cel.NewEnv(
+	cel.Variable("status", cel.DynType),
+	cel.Variable("metadata", cel.DynType),
+	cel.Variable("spec", cel.DynType),
+)
+
+// celProgramCache compiles and caches CEL programs for a single
+// Kustomization, keyed by the generation its HealthCheckExprs were declared
+// at, so that editing the expressions invalidates the cache while repeated
+// polls of the Wait loop against the same generation reuse it.
+type celProgramCache struct {
+	mu         sync.Mutex
+	generation int64
+	programs   map[string]cel.Program
+}
+
+func (c *celProgramCache) programFor(generation int64, expr string) (cel.Program, error) {
+	if celEnvErr != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", celEnvErr)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generation != generation || c.programs == nil {
+		c.programs = make(map[string]cel.Program)
+		c.generation = generation
+	}
+
+	if prg, ok := c.programs[expr]; ok {
+		return prg, nil
+	}
+
+	ast, iss := celEnv.Compile(expr)
+	if iss != nil && iss.Err() != nil {
+		return nil, fmt.Errorf("failed to compile CEL expression %q: %w", expr, iss.Err())
+	}
+
+	prg, err := celEnv.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build CEL program for %q: %w", expr, err)
+	}
+
+	c.programs[expr] = prg
+	return prg, nil
+}
+
+// evaluate runs expr against obj's status, metadata and spec fields.
+func (c *celProgramCache) evaluate(generation int64, expr string, obj *unstructured.Unstructured) (bool, error) {
+	prg, err := c.programFor(generation, expr)
+	if err != nil {
+		return false, err
+	}
+
+	out, _, err := prg.Eval(map[string]interface{}{
+		"status":   obj.Object["status"],
+		"metadata": obj.Object["metadata"],
+		"spec":     obj.Object["spec"],
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate CEL expression %q for %s %s: %w", expr, obj.GetKind(), obj.GetName(), err)
+	}
+
+	healthy, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("CEL expression %q for %s %s did not evaluate to a bool", expr, obj.GetKind(), obj.GetName())
+	}
+
+	return healthy, nil
+}
+
+// CELHealthAssessor evaluates HealthCheckExprs against live objects, with
+// one compiled-program cache per Kustomization so that the Wait loop's
+// repeated polling doesn't recompile the same expressions every tick.
+type CELHealthAssessor struct {
+	mu     sync.Mutex
+	caches map[types.NamespacedName]*celProgramCache
+}
+
+// NewCELHealthAssessor returns an empty CELHealthAssessor.
+func NewCELHealthAssessor() *CELHealthAssessor {
+	return &CELHealthAssessor{caches: make(map[types.NamespacedName]*celProgramCache)}
+}
+
+// IsHealthy reports whether obj satisfies the HealthCheckExpr declared for
+// its apiVersion and kind in exprs, for the Kustomization identified by nn
+// at the given generation. It returns true when no HealthCheckExpr targets
+// obj's apiVersion/kind, since such resources are outside the CEL
+// assessment's scope.
+func (a *CELHealthAssessor) IsHealthy(nn types.NamespacedName, generation int64, exprs []kustomizev1.HealthCheckExpr, obj *unstructured.Unstructured) (bool, error) {
+	apiVersion, kind := obj.GetAPIVersion(), obj.GetKind()
+
+	for _, hc := range exprs {
+		if hc.APIVersion != apiVersion || hc.Kind != kind {
+			continue
+		}
+
+		a.mu.Lock()
+		cache, ok := a.caches[nn]
+		if !ok {
+			cache = &celProgramCache{}
+			a.caches[nn] = cache
+		}
+		a.mu.Unlock()
+
+		return cache.evaluate(generation, hc.Expr, obj)
+	}
+
+	return true, nil
+}
+
+// RecordHealthCheckExprError sets k's HealthyCondition to False with err's
+// message, so that a CEL expression that fails to evaluate is surfaced to
+// users instead of silently passing or hanging the Wait loop.
+func RecordHealthCheckExprError(k *kustomizev1.Kustomization, reason string, err error) {
+	kustomizev1.SetKustomizationHealthiness(k, metav1.ConditionFalse, reason, err.Error())
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+)
+
+func newDatabase(phase string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Database",
+		"metadata": map[string]interface{}{
+			"name": "my-db",
+		},
+		"status": map[string]interface{}{
+			"phase": phase,
+		},
+	}}
+}
+
+func TestCELHealthAssessor_IsHealthy(t *testing.T) {
+	g := NewWithT(t)
+
+	exprs := []kustomizev1.HealthCheckExpr{
+		{APIVersion: "example.com/v1", Kind: "Database", Expr: "status.phase == 'Ready'"},
+	}
+	nn := types.NamespacedName{Namespace: "default", Name: "my-kustomization"}
+	assessor := NewCELHealthAssessor()
+
+	healthy, err := assessor.IsHealthy(nn, 1, exprs, newDatabase("Provisioning"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(healthy).To(BeFalse())
+
+	healthy, err = assessor.IsHealthy(nn, 1, exprs, newDatabase("Ready"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(healthy).To(BeTrue())
+}
+
+func TestCELHealthAssessor_NoMatchingExprIsHealthy(t *testing.T) {
+	g := NewWithT(t)
+
+	exprs := []kustomizev1.HealthCheckExpr{
+		{APIVersion: "example.com/v1", Kind: "Cluster", Expr: "status.phase == 'Ready'"},
+	}
+	nn := types.NamespacedName{Namespace: "default", Name: "my-kustomization"}
+	assessor := NewCELHealthAssessor()
+
+	healthy, err := assessor.IsHealthy(nn, 1, exprs, newDatabase("Provisioning"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(healthy).To(BeTrue())
+}
+
+func TestCELHealthAssessor_InvalidExprSurfacesError(t *testing.T) {
+	g := NewWithT(t)
+
+	exprs := []kustomizev1.HealthCheckExpr{
+		{APIVersion: "example.com/v1", Kind: "Database", Expr: "status.phase =="},
+	}
+	nn := types.NamespacedName{Namespace: "default", Name: "my-kustomization"}
+	assessor := NewCELHealthAssessor()
+
+	_, err := assessor.IsHealthy(nn, 1, exprs, newDatabase("Ready"))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestCELProgramCache_InvalidatesOnGenerationChange(t *testing.T) {
+	g := NewWithT(t)
+
+	cache := &celProgramCache{}
+
+	prgA, err := cache.programFor(1, "status.phase == 'Ready'")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	prgAAgain, err := cache.programFor(1, "status.phase == 'Ready'")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(prgAAgain).To(BeIdenticalTo(prgA))
+
+	prgB, err := cache.programFor(2, "status.phase == 'Ready'")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(prgB).ToNot(BeIdenticalTo(prgA))
+}
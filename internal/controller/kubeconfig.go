@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+)
+
+// BuildKubeConfig builds the *rest.Config used to reach the remote cluster
+// referenced by a Kustomization's KubeConfig secret, and applies
+// impersonation for the apply, prune and health-check calls made against it.
+// namespace is the Kustomization's own namespace, used to scope the
+// impersonated ServiceAccountName when TargetNamespace is not set.
+func BuildKubeConfig(kubeConfigData []byte, spec kustomizev1.KustomizationSpec, namespace string) (*rest.Config, error) {
+	restCfg, err := clientcmd.RESTConfigFromKubeConfig(kubeConfigData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build REST config from KubeConfig secret: %w", err)
+	}
+
+	if imp := impersonationConfig(spec, namespace); imp != nil {
+		restCfg.Impersonate = *imp
+	}
+
+	return restCfg, nil
+}
+
+// impersonationConfig returns the rest.ImpersonationConfig to apply for a
+// remote-cluster reconciliation, or nil when none is configured. namespace is
+// the Kustomization's own namespace.
+//
+// KubeConfig.Impersonate, when set, is used verbatim. Otherwise, if
+// ServiceAccountName is set, the controller impersonates that service
+// account in TargetNamespace, the same identity it would use to reconcile
+// in-cluster, so that RBAC is enforced per tenant namespace even when the
+// apply happens on a remote cluster reached through KubeConfig. When
+// TargetNamespace is not set, the apply happens in the Kustomization's own
+// namespace, so the impersonated identity falls back to namespace too.
+func impersonationConfig(spec kustomizev1.KustomizationSpec, namespace string) *rest.ImpersonationConfig {
+	if spec.KubeConfig == nil {
+		return nil
+	}
+
+	if imp := spec.KubeConfig.Impersonate; imp != nil {
+		return &rest.ImpersonationConfig{
+			UserName: imp.UserName,
+			Groups:   imp.Groups,
+			UID:      imp.UID,
+		}
+	}
+
+	if spec.ServiceAccountName == "" {
+		return nil
+	}
+
+	ns := spec.TargetNamespace
+	if ns == "" {
+		ns = namespace
+	}
+
+	return &rest.ImpersonationConfig{
+		UserName: fmt.Sprintf("system:serviceaccount:%s:%s", ns, spec.ServiceAccountName),
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+)
+
+func TestImpersonationConfig(t *testing.T) {
+	t.Run("no KubeConfig means no impersonation", func(t *testing.T) {
+		g := NewWithT(t)
+
+		imp := impersonationConfig(kustomizev1.KustomizationSpec{
+			ServiceAccountName: "reconciler",
+		}, "kust-ns")
+		g.Expect(imp).To(BeNil())
+	})
+
+	t.Run("KubeConfig without Impersonate or ServiceAccountName is a no-op", func(t *testing.T) {
+		g := NewWithT(t)
+
+		imp := impersonationConfig(kustomizev1.KustomizationSpec{
+			KubeConfig: &kustomizev1.KubeConfig{},
+		}, "kust-ns")
+		g.Expect(imp).To(BeNil())
+	})
+
+	t.Run("ServiceAccountName is impersonated as system:serviceaccount", func(t *testing.T) {
+		g := NewWithT(t)
+
+		imp := impersonationConfig(kustomizev1.KustomizationSpec{
+			KubeConfig:         &kustomizev1.KubeConfig{},
+			ServiceAccountName: "reconciler",
+			TargetNamespace:    "tenant-a",
+		}, "kust-ns")
+		g.Expect(imp).ToNot(BeNil())
+		g.Expect(imp.UserName).To(Equal("system:serviceaccount:tenant-a:reconciler"))
+	})
+
+	t.Run("unset TargetNamespace falls back to the Kustomization's own namespace", func(t *testing.T) {
+		g := NewWithT(t)
+
+		imp := impersonationConfig(kustomizev1.KustomizationSpec{
+			KubeConfig:         &kustomizev1.KubeConfig{},
+			ServiceAccountName: "reconciler",
+		}, "kust-ns")
+		g.Expect(imp).ToNot(BeNil())
+		g.Expect(imp.UserName).To(Equal("system:serviceaccount:kust-ns:reconciler"))
+	})
+
+	t.Run("explicit Impersonate takes precedence over ServiceAccountName", func(t *testing.T) {
+		g := NewWithT(t)
+
+		imp := impersonationConfig(kustomizev1.KustomizationSpec{
+			KubeConfig: &kustomizev1.KubeConfig{
+				Impersonate: &kustomizev1.ImpersonationConfig{
+					UserName: "custom-user",
+					Groups:   []string{"system:masters"},
+					UID:      "1000",
+				},
+			},
+			ServiceAccountName: "reconciler",
+			TargetNamespace:    "tenant-a",
+		}, "kust-ns")
+		g.Expect(imp).ToNot(BeNil())
+		g.Expect(imp.UserName).To(Equal("custom-user"))
+		g.Expect(imp.Groups).To(Equal([]string{"system:masters"}))
+		g.Expect(imp.UID).To(Equal("1000"))
+	})
+}
+
+const testKubeConfig = `
+apiVersion: v1
+kind: Config
+clusters:
+  - name: remote
+    cluster:
+      server: https://remote.example.com
+users:
+  - name: remote
+    user:
+      token: test-token
+contexts:
+  - name: remote
+    context:
+      cluster: remote
+      user: remote
+current-context: remote
+`
+
+func TestBuildKubeConfig(t *testing.T) {
+	t.Run("unset TargetNamespace falls back to the Kustomization's own namespace", func(t *testing.T) {
+		g := NewWithT(t)
+
+		restCfg, err := BuildKubeConfig([]byte(testKubeConfig), kustomizev1.KustomizationSpec{
+			KubeConfig:         &kustomizev1.KubeConfig{},
+			ServiceAccountName: "reconciler",
+		}, "kust-ns")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(restCfg.Impersonate.UserName).To(Equal("system:serviceaccount:kust-ns:reconciler"))
+	})
+
+	t.Run("TargetNamespace takes precedence over the Kustomization's own namespace", func(t *testing.T) {
+		g := NewWithT(t)
+
+		restCfg, err := BuildKubeConfig([]byte(testKubeConfig), kustomizev1.KustomizationSpec{
+			KubeConfig:         &kustomizev1.KubeConfig{},
+			ServiceAccountName: "reconciler",
+			TargetNamespace:    "tenant-a",
+		}, "kust-ns")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(restCfg.Impersonate.UserName).To(Equal("system:serviceaccount:tenant-a:reconciler"))
+	})
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package decryptor resolves the credentials referenced by a Kustomization's
+// Decryption spec and makes them available to SOPS, which reads most of its
+// non-PGP master key providers straight out of the process environment.
+package decryptor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	sopsdecrypt "github.com/getsops/sops/v3/decrypt"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+)
+
+// importMu serializes every ImportKeys/Decrypt/cleanup span, across all
+// Decryptor instances. SOPS' age, Vault, AWS, Azure and GCP master key
+// implementations all read their credentials from the process environment
+// rather than accepting them as explicit arguments, and OpenPGP import
+// shells out to gpg with GNUPGHOME set in the environment. With concurrent
+// reconciliations (--concurrent > 1), two Kustomizations decrypting with
+// different Decryption secrets at the same time would otherwise race
+// os.Setenv/os.Getenv and could decrypt with each other's credentials.
+// Holding this lock for the whole import-decrypt-cleanup span trades that
+// for decryption running one Kustomization at a time.
+var importMu sync.Mutex
+
+// Decryptor resolves the secret material referenced by a Kustomization's
+// Decryption spec into the environment SOPS expects, and decrypts manifests
+// with the resulting keys.
+type Decryptor struct {
+	client    ctrlclient.Client
+	namespace string
+}
+
+// NewDecryptor returns a Decryptor that resolves Decryption secret refs
+// against Secrets in namespace using c.
+func NewDecryptor(c ctrlclient.Client, namespace string) *Decryptor {
+	return &Decryptor{client: c, namespace: namespace}
+}
+
+// ImportKeys acquires importMu, fetches the secrets referenced by d, and
+// configures the process environment and a scratch GNUPGHOME so that SOPS'
+// OpenPGP, age, Vault and cloud KMS master key implementations can locate
+// them. The returned cleanup function removes the scratch directory,
+// restores the environment and releases importMu; it is safe to call more
+// than once, but must be called at least once, after the Decrypt calls that
+// depend on this import have completed. ImportKeys is a no-op, and does not
+// acquire importMu, when d is nil.
+func (dec *Decryptor) ImportKeys(ctx context.Context, d *kustomizev1.Decryption) (func(), error) {
+	if d == nil {
+		return func() {}, nil
+	}
+
+	importMu.Lock()
+
+	dir, err := os.MkdirTemp("", "kustomize-controller-decryption")
+	if err != nil {
+		importMu.Unlock()
+		return nil, fmt.Errorf("failed to create decryption scratch dir: %w", err)
+	}
+
+	var restores []func()
+	var once sync.Once
+	cleanup := func() {
+		once.Do(func() {
+			for _, restore := range restores {
+				restore()
+			}
+			os.RemoveAll(dir)
+			importMu.Unlock()
+		})
+	}
+
+	if d.SecretRef != nil {
+		data, err := dec.secret(ctx, d.SecretRef.Name)
+		if err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to get OpenPGP secret %q: %w", d.SecretRef.Name, err)
+		}
+		home, err := importPGPKeyring(dir, data)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		restores = append(restores, setEnv("GNUPGHOME", home))
+	}
+
+	if d.AgeSecretRef != nil {
+		data, err := dec.secret(ctx, d.AgeSecretRef.Name)
+		if err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to get age secret %q: %w", d.AgeSecretRef.Name, err)
+		}
+		keyFile := filepath.Join(dir, "age.agekey")
+		if err := os.WriteFile(keyFile, data["age.agekey"], 0o600); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to write age identity file: %w", err)
+		}
+		restores = append(restores, setEnv("SOPS_AGE_KEY_FILE", keyFile))
+	}
+
+	if d.VaultTokenSecretRef != nil {
+		data, err := dec.secret(ctx, d.VaultTokenSecretRef.Name)
+		if err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to get Vault secret %q: %w", d.VaultTokenSecretRef.Name, err)
+		}
+		restores = append(restores,
+			setEnv("VAULT_TOKEN", string(data["VAULT_TOKEN"])),
+			setEnv("VAULT_ADDR", string(data["VAULT_ADDR"])),
+		)
+	}
+
+	if d.AWSSecretRef != nil {
+		data, err := dec.secret(ctx, d.AWSSecretRef.Name)
+		if err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to get AWS secret %q: %w", d.AWSSecretRef.Name, err)
+		}
+		restores = append(restores,
+			setEnv("AWS_ACCESS_KEY_ID", string(data["AWS_ACCESS_KEY_ID"])),
+			setEnv("AWS_SECRET_ACCESS_KEY", string(data["AWS_SECRET_ACCESS_KEY"])),
+		)
+	}
+
+	if d.AzureKeyVaultSecretRef != nil {
+		data, err := dec.secret(ctx, d.AzureKeyVaultSecretRef.Name)
+		if err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to get Azure Key Vault secret %q: %w", d.AzureKeyVaultSecretRef.Name, err)
+		}
+		restores = append(restores,
+			setEnv("AZURE_TENANT_ID", string(data["tenantId"])),
+			setEnv("AZURE_CLIENT_ID", string(data["clientId"])),
+			setEnv("AZURE_CLIENT_SECRET", string(data["clientSecret"])),
+		)
+	}
+
+	if d.GCPServiceAccountSecretRef != nil {
+		data, err := dec.secret(ctx, d.GCPServiceAccountSecretRef.Name)
+		if err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to get GCP service account secret %q: %w", d.GCPServiceAccountSecretRef.Name, err)
+		}
+		keyFile := filepath.Join(dir, "sa.json")
+		if err := os.WriteFile(keyFile, data["sa.json"], 0o600); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to write GCP service account file: %w", err)
+		}
+		restores = append(restores, setEnv("GOOGLE_APPLICATION_CREDENTIALS", keyFile))
+	}
+
+	return cleanup, nil
+}
+
+// Decrypt decrypts the given SOPS-encrypted YAML document using whichever
+// keys ImportKeys configured in the environment. It must only be called
+// between a call to ImportKeys and its cleanup function, so that it runs
+// while importMu is held.
+func (dec *Decryptor) Decrypt(data []byte) ([]byte, error) {
+	out, err := sopsdecrypt.Data(data, "yaml")
+	if err != nil {
+		return nil, fmt.Errorf("sops decryption failed: %w", err)
+	}
+	return out, nil
+}
+
+func (dec *Decryptor) secret(ctx context.Context, name string) (map[string][]byte, error) {
+	var secret corev1.Secret
+	if err := dec.client.Get(ctx, types.NamespacedName{Namespace: dec.namespace, Name: name}, &secret); err != nil {
+		return nil, err
+	}
+	return secret.Data, nil
+}
+
+// setEnv sets key to value and returns a function that restores the
+// environment variable to whatever it was before.
+func setEnv(key, value string) func() {
+	previous, had := os.LookupEnv(key)
+	os.Setenv(key, value)
+	return func() {
+		if had {
+			os.Setenv(key, previous)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
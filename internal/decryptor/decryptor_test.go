@@ -0,0 +1,159 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decryptor
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	"github.com/fluxcd/pkg/apis/meta"
+)
+
+func TestImportKeys_Vault(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "vault-creds", Namespace: "default"},
+		Data: map[string][]byte{
+			"VAULT_TOKEN": []byte("s.abc123"),
+			"VAULT_ADDR":  []byte("https://vault.internal:8200"),
+		},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	dec := NewDecryptor(c, "default")
+
+	cleanup, err := dec.ImportKeys(context.Background(), &kustomizev1.Decryption{
+		Provider:            "sops",
+		VaultTokenSecretRef: &meta.LocalObjectReference{Name: "vault-creds"},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	defer cleanup()
+
+	g.Expect(os.Getenv("VAULT_TOKEN")).To(Equal("s.abc123"))
+	g.Expect(os.Getenv("VAULT_ADDR")).To(Equal("https://vault.internal:8200"))
+}
+
+func TestImportKeys_CleansUpEnv(t *testing.T) {
+	g := NewWithT(t)
+
+	os.Setenv("AWS_ACCESS_KEY_ID", "pre-existing")
+	defer os.Unsetenv("AWS_ACCESS_KEY_ID")
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "aws-creds", Namespace: "default"},
+		Data: map[string][]byte{
+			"AWS_ACCESS_KEY_ID":     []byte("AKIA..."),
+			"AWS_SECRET_ACCESS_KEY": []byte("secret"),
+		},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	dec := NewDecryptor(c, "default")
+
+	cleanup, err := dec.ImportKeys(context.Background(), &kustomizev1.Decryption{
+		Provider:     "sops",
+		AWSSecretRef: &meta.LocalObjectReference{Name: "aws-creds"},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(os.Getenv("AWS_ACCESS_KEY_ID")).To(Equal("AKIA..."))
+
+	cleanup()
+	g.Expect(os.Getenv("AWS_ACCESS_KEY_ID")).To(Equal("pre-existing"))
+}
+
+func TestImportKeys_CleanupIsIdempotent(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "aws-creds", Namespace: "default"},
+		Data: map[string][]byte{
+			"AWS_ACCESS_KEY_ID":     []byte("AKIA..."),
+			"AWS_SECRET_ACCESS_KEY": []byte("secret"),
+		},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	dec := NewDecryptor(c, "default")
+
+	cleanup, err := dec.ImportKeys(context.Background(), &kustomizev1.Decryption{
+		Provider:     "sops",
+		AWSSecretRef: &meta.LocalObjectReference{Name: "aws-creds"},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(func() {
+		cleanup()
+		cleanup()
+	}).ToNot(Panic())
+}
+
+func TestImportKeys_Nil(t *testing.T) {
+	g := NewWithT(t)
+
+	dec := NewDecryptor(nil, "default")
+	cleanup, err := dec.ImportKeys(context.Background(), nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cleanup).ToNot(BeNil())
+	cleanup()
+}
+
+// TestImportKeys_SerializesAcrossCalls guards against importMu being left
+// locked by a previous cleanup: two back-to-back ImportKeys/cleanup cycles
+// must both complete, not hang waiting on a lock the first cycle never
+// released.
+func TestImportKeys_SerializesAcrossCalls(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "aws-creds", Namespace: "default"},
+		Data: map[string][]byte{
+			"AWS_ACCESS_KEY_ID":     []byte("AKIA..."),
+			"AWS_SECRET_ACCESS_KEY": []byte("secret"),
+		},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	dec := NewDecryptor(c, "default")
+
+	for i := 0; i < 2; i++ {
+		cleanup, err := dec.ImportKeys(context.Background(), &kustomizev1.Decryption{
+			Provider:     "sops",
+			AWSSecretRef: &meta.LocalObjectReference{Name: "aws-creds"},
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(os.Getenv("AWS_ACCESS_KEY_ID")).To(Equal("AKIA..."))
+		cleanup()
+	}
+}
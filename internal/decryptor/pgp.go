@@ -0,0 +1,47 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decryptor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// importPGPKeyring imports every OpenPGP private key found in data into a
+// dedicated GNUPGHOME keyring under dir, so SOPS can use it for decryption
+// without touching the controller process' own keyring. It returns the
+// GNUPGHOME path.
+func importPGPKeyring(dir string, data map[string][]byte) (string, error) {
+	home := filepath.Join(dir, "gnupg")
+	if err := os.MkdirAll(home, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create GNUPGHOME: %w", err)
+	}
+
+	for name, key := range data {
+		cmd := exec.Command("gpg", "--batch", "--import")
+		cmd.Env = append(os.Environ(), "GNUPGHOME="+home)
+		cmd.Stdin = bytes.NewReader(key)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("failed to import OpenPGP key %q: %w: %s", name, err, out)
+		}
+	}
+
+	return home, nil
+}